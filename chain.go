@@ -0,0 +1,88 @@
+package onion
+
+import (
+	"net/http"
+)
+
+// Constructor adapts an http.Handler by wrapping it with another http.Handler,
+// following the convention used across the net/http middleware ecosystem (e.g.
+// justinas/alice). It is the same shape as Handler's yield side, just
+// expressed the other way around.
+type Constructor func(http.Handler) http.Handler
+
+// Chain is an immutable list of Constructors that are applied in order,
+// outermost first, to build up an http.Handler. Chain is interoperable with
+// the alice-style middleware ecosystem: any func(http.Handler) http.Handler
+// can be passed to NewChain or Append.
+type Chain struct {
+	constructors []Constructor
+}
+
+// NewChain creates a new Chain from the given constructors, applied in the
+// order passed to Then/ThenFunc.
+func NewChain(ctors ...func(http.Handler) http.Handler) Chain {
+	constructors := make([]Constructor, len(ctors))
+	for i, ctor := range ctors {
+		constructors[i] = ctor
+	}
+	return Chain{constructors: constructors}
+}
+
+// Then chains the constructors and returns the final http.Handler.
+//     NewChain(m1, m2, m3).Then(h)
+// is equivalent to
+//     m1(m2(m3(h)))
+func (c Chain) Then(h http.Handler) http.Handler {
+	for i := len(c.constructors) - 1; i >= 0; i-- {
+		h = c.constructors[i](h)
+	}
+	return h
+}
+
+// ThenFunc works like Then, but takes a http.HandlerFunc instead of a
+// http.Handler.
+func (c Chain) ThenFunc(fn http.HandlerFunc) http.Handler {
+	return c.Then(fn)
+}
+
+// Append extends a Chain, adding the specified constructors as the last ones
+// in the request flow, and returns the new Chain. Append does not modify the
+// receiver.
+func (c Chain) Append(ctors ...func(http.Handler) http.Handler) Chain {
+	newCons := make([]Constructor, 0, len(c.constructors)+len(ctors))
+	newCons = append(newCons, c.constructors...)
+	for _, ctor := range ctors {
+		newCons = append(newCons, ctor)
+	}
+	return Chain{constructors: newCons}
+}
+
+// Extend extends a Chain by adding the specified Chain as the last one in
+// the request flow, and returns the new Chain. Extend does not modify the
+// receiver.
+func (c Chain) Extend(chain Chain) Chain {
+	newCons := make([]Constructor, 0, len(c.constructors)+len(chain.constructors))
+	newCons = append(newCons, c.constructors...)
+	newCons = append(newCons, chain.constructors...)
+	return Chain{constructors: newCons}
+}
+
+// FromConstructor adapts a standard func(http.Handler) http.Handler
+// middleware constructor into an onion.Handler, so alice-style middleware
+// can be passed to Onion.Use alongside onion-native Handlers.
+func FromConstructor(ctor func(http.Handler) http.Handler) Handler {
+	return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		ctor(next).ServeHTTP(rw, r)
+	})
+}
+
+// ToConstructor adapts a Handler into a func(http.Handler) http.Handler
+// constructor, so onion-native Handlers can be used with Chain or any other
+// alice-style middleware stack.
+func ToConstructor(h Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(rw, r, next)
+		})
+	}
+}
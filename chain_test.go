@@ -0,0 +1,121 @@
+package onion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChainThen(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	mkCtor := func(tag string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				result += tag
+				next.ServeHTTP(rw, r)
+			})
+		}
+	}
+
+	final := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "final"
+	})
+
+	c := NewChain(mkCtor("one"), mkCtor("two"))
+	c.Then(final).ServeHTTP(response, (*http.Request)(nil))
+
+	expect(t, result, "onetwofinal")
+}
+
+func TestChainThenFunc(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	c := NewChain()
+	handler := c.ThenFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result = "handled"
+	})
+	handler.ServeHTTP(response, (*http.Request)(nil))
+
+	expect(t, result, "handled")
+}
+
+func TestChainAppend_doesNotModifyOriginal(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	mkCtor := func(tag string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				result += tag
+				next.ServeHTTP(rw, r)
+			})
+		}
+	}
+	final := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+
+	c1 := NewChain(mkCtor("one"))
+	c2 := c1.Append(mkCtor("two"))
+
+	expect(t, 1, len(c1.constructors))
+	expect(t, 2, len(c2.constructors))
+
+	result = ""
+	c1.Then(final).ServeHTTP(response, (*http.Request)(nil))
+	expect(t, result, "one")
+}
+
+func TestChainExtend(t *testing.T) {
+	response := httptest.NewRecorder()
+	result := ""
+
+	mkCtor := func(tag string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				result += tag
+				next.ServeHTTP(rw, r)
+			})
+		}
+	}
+	final := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})
+
+	c1 := NewChain(mkCtor("one"))
+	c2 := NewChain(mkCtor("two"))
+	combined := c1.Extend(c2)
+
+	combined.Then(final).ServeHTTP(response, (*http.Request)(nil))
+	expect(t, result, "onetwo")
+}
+
+func TestFromConstructorAndToConstructor(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	ctor := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			result += "ctor-"
+			next.ServeHTTP(rw, r)
+		})
+	}
+
+	n := New()
+	n.Use(FromConstructor(ctor))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "handler"
+	})
+	n.ServeHTTP(response, (*http.Request)(nil))
+	expect(t, result, "ctor-handler")
+
+	result = ""
+	onionHandler := HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		result += "onion-"
+		next.ServeHTTP(rw, r)
+	})
+	back := ToConstructor(onionHandler)
+	back(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "final"
+	})).ServeHTTP(response, (*http.Request)(nil))
+	expect(t, result, "onion-final")
+}
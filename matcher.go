@@ -0,0 +1,57 @@
+package onion
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Predicate reports whether a Handler guarded by When should run for r.
+type Predicate func(r *http.Request) bool
+
+// When returns a Handler that only runs h when pred(r) is true; otherwise it
+// skips h and invokes next directly. This lets middleware like auth or CORS
+// apply selectively to a subset of requests without splitting the chain
+// into multiple Onion instances.
+func When(pred Predicate, h Handler) Handler {
+	return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		if !pred(r) {
+			next.ServeHTTP(rw, r)
+			return
+		}
+		h.ServeHTTP(rw, r, next)
+	})
+}
+
+// MatchMethod returns a Predicate that matches requests whose method is one
+// of methods.
+func MatchMethod(methods ...string) Predicate {
+	return func(r *http.Request) bool {
+		for _, method := range methods {
+			if r.Method == method {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchHost returns a Predicate that matches requests whose Host is one of
+// hosts.
+func MatchHost(hosts ...string) Predicate {
+	return func(r *http.Request) bool {
+		for _, host := range hosts {
+			if r.Host == host {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchPathPrefix returns a Predicate that matches requests whose URL path
+// starts with prefix.
+func MatchPathPrefix(prefix string) Predicate {
+	return func(r *http.Request) bool {
+		return strings.HasPrefix(r.URL.Path, prefix)
+	}
+}
@@ -0,0 +1,68 @@
+package onion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWhenRunsHandlerWhenPredicateTrue(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	h := When(func(r *http.Request) bool { return true }, HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		result += "matched-"
+		next.ServeHTTP(rw, r)
+	}))
+
+	n := New(h)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "handler"
+	})
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	expect(t, result, "matched-handler")
+}
+
+func TestWhenSkipsHandlerWhenPredicateFalse(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	h := When(func(r *http.Request) bool { return false }, HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		result += "matched-"
+		next.ServeHTTP(rw, r)
+	}))
+
+	n := New(h)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "handler"
+	})
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	expect(t, result, "handler")
+}
+
+func TestMatchMethod(t *testing.T) {
+	pred := MatchMethod("POST", "PUT")
+
+	expect(t, pred(httptest.NewRequest("POST", "/", nil)), true)
+	expect(t, pred(httptest.NewRequest("PUT", "/", nil)), true)
+	expect(t, pred(httptest.NewRequest("GET", "/", nil)), false)
+}
+
+func TestMatchHost(t *testing.T) {
+	pred := MatchHost("example.com")
+
+	req := httptest.NewRequest("GET", "http://example.com/", nil)
+	expect(t, pred(req), true)
+
+	req = httptest.NewRequest("GET", "http://other.com/", nil)
+	expect(t, pred(req), false)
+}
+
+func TestMatchPathPrefix(t *testing.T) {
+	pred := MatchPathPrefix("/api")
+
+	expect(t, pred(httptest.NewRequest("GET", "/api/widgets", nil)), true)
+	expect(t, pred(httptest.NewRequest("GET", "/web", nil)), false)
+}
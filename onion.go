@@ -2,6 +2,8 @@ package onion
 
 import (
 	"net/http"
+	"strings"
+	"sync"
 )
 
 // Handler handler is an interface that objects can implement to be registered to serve as middleware
@@ -22,19 +24,31 @@ func (h HandlerFunc) ServeHTTP(rw http.ResponseWriter, r *http.Request, next htt
 	h(rw, r, next)
 }
 
-type middleware struct {
-	handler Handler
-
-	// next stores the next.ServeHTTP to reduce memory allocate
-	next http.Handler
+// iterator walks a fixed []Handler for a single request, advancing its
+// index on each call to ServeHTTP instead of recursing through a tree of
+// allocated nodes. Iterators are pooled so that dispatching a chain does
+// not allocate per request, regardless of chain depth.
+type iterator struct {
+	handlers []Handler
+	index    int
+	terminal http.Handler
 }
 
-func (m middleware) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
-	if m.handler == nil {
+func (it *iterator) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if it.index >= len(it.handlers) {
+		if it.terminal != nil {
+			it.terminal.ServeHTTP(rw, r)
+		}
 		return
 	}
 
-	m.handler.ServeHTTP(rw, r, m.next)
+	h := it.handlers[it.index]
+	it.index++
+	h.ServeHTTP(rw, r, it)
+}
+
+var iteratorPool = sync.Pool{
+	New: func() interface{} { return new(iterator) },
 }
 
 // Wrap converts a http.Handler into a onion.Handler so it can be used as a Onion
@@ -61,16 +75,14 @@ func WrapFunc(handlerFunc http.HandlerFunc) Handler {
 // Onion middleware is evaluated in the order that they are added to the stack using
 // the Use and UseHandler methods.
 type Onion struct {
-	middleware middleware
-	handlers   []Handler
+	handlers []Handler
+	mux      *http.ServeMux
+	groupMux *http.ServeMux
 }
 
 // New returns a new Onion instance with no middleware preconfigured.
 func New(handlers ...Handler) *Onion {
-	return &Onion{
-		handlers:   handlers,
-		middleware: build(handlers),
-	}
+	return &Onion{handlers: handlers}
 }
 
 // With returns a new Onion instance that is a combination of the onion
@@ -83,8 +95,96 @@ func (n *Onion) With(handlers ...Handler) *Onion {
 	)
 }
 
+// Group returns a new Onion preconfigured with an immutable snapshot of the
+// receiver's handlers (the same copy-on-write guarantee as With) followed
+// by handlers, and routes requests under prefix directly to it, ahead of
+// the receiver's own handler chain. Because the snapshot is taken at
+// Group's call time, handlers added to the receiver afterwards do not
+// retroactively affect the group. Attach further handlers or mounts to the
+// returned Onion to build out the sub-chain.
+func (n *Onion) Group(prefix string, handlers ...Handler) *Onion {
+	sub := n.With(handlers...)
+
+	if n.groupMux == nil {
+		n.groupMux = http.NewServeMux()
+	}
+	pattern := mountPattern(prefix)
+	n.groupMux.Handle(pattern, http.StripPrefix(strings.TrimSuffix(pattern, "/"), sub))
+
+	return sub
+}
+
+// Mount registers h to serve requests whose path starts with prefix,
+// stripping the prefix before dispatch. Mounted handlers are reached only
+// once the receiver's middleware stack has run next through to the end of
+// the chain, so the receiver's handlers still wrap the mounted subtree.
+// Prefixes registered with Group are matched earlier, ahead of the
+// receiver's own handlers, unless the matching Mount pattern is longer
+// than the Group prefix; see Group.
+func (n *Onion) Mount(prefix string, h http.Handler) {
+	if n.mux == nil {
+		n.mux = http.NewServeMux()
+	}
+
+	pattern := mountPattern(prefix)
+	n.mux.Handle(pattern, http.StripPrefix(strings.TrimSuffix(pattern, "/"), h))
+}
+
+// mountPattern normalizes prefix into a trailing-slash http.ServeMux
+// subtree pattern, as used by both Mount and Group.
+func mountPattern(prefix string) string {
+	if strings.HasSuffix(prefix, "/") {
+		return prefix
+	}
+	return prefix + "/"
+}
+
 func (n *Onion) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
-	n.middleware.ServeHTTP(rw, r)
+	// Wrap rw once, the first time it reaches the chain, so every
+	// downstream handler sees the same enriched onion.ResponseWriter.
+	var wrapped ResponseWriter
+	if _, ok := rw.(ResponseWriter); !ok {
+		underlying := rw
+		wrapped = wrapOptional(getResponseWriter(underlying), underlying)
+		rw = wrapped
+	}
+
+	// Requests matching a Group's prefix are routed straight to the
+	// group's own immutable snapshot, bypassing the receiver's live
+	// handlers entirely; the snapshot already includes them as they stood
+	// at Group's call time. A pattern registered with Mount still wins
+	// when it is more specific than the matching Group prefix.
+	var terminal http.Handler
+	var mountHandler http.Handler
+	var mountPattern string
+	if n.mux != nil {
+		mountHandler, mountPattern = n.mux.Handler(r)
+		if mountPattern != "" {
+			terminal = mountHandler
+		}
+	}
+
+	if n.groupMux != nil {
+		if h, groupPattern := n.groupMux.Handler(r); groupPattern != "" && len(groupPattern) >= len(mountPattern) {
+			h.ServeHTTP(rw, r)
+			if wrapped != nil {
+				putWrapped(wrapped)
+			}
+			return
+		}
+	}
+
+	it := iteratorPool.Get().(*iterator)
+	it.handlers = n.handlers
+	it.index = 0
+	it.terminal = terminal
+
+	it.ServeHTTP(rw, r)
+
+	iteratorPool.Put(it)
+	if wrapped != nil {
+		putWrapped(wrapped)
+	}
 }
 
 // Use adds a Handler onto the middleware stack. Handlers are invoked in the order they are added to a Onion.
@@ -94,7 +194,6 @@ func (n *Onion) Use(handler Handler) {
 	}
 
 	n.handlers = append(n.handlers, handler)
-	n.middleware = build(n.handlers)
 }
 
 // UseFunc adds a Onion-style handler function onto the middleware stack.
@@ -116,16 +215,3 @@ func (n *Onion) UseHandlerFunc(handlerFunc func(rw http.ResponseWriter, r *http.
 func (n *Onion) Handlers() []Handler {
 	return n.handlers
 }
-
-func build(handlers []Handler) middleware {
-	if len(handlers) == 0 {
-		return middleware{}
-	}
-
-	var next middleware
-	if len(handlers) > 1 {
-		next = build(handlers[1:])
-	}
-
-	return middleware{handler: handlers[0], next: &next}
-}
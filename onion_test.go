@@ -185,6 +185,126 @@ func TestWrapFunc(t *testing.T) {
 	expect(t, response.Code, http.StatusOK)
 }
 
+func TestOnionMount(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	n := New()
+	n.Use(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		result += "outer-"
+		next.ServeHTTP(rw, r)
+	}))
+	n.Mount("/api", http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "mounted:" + r.URL.Path
+	}))
+
+	req, _ := http.NewRequest("GET", "/api/widgets", nil)
+	n.ServeHTTP(response, req)
+
+	expect(t, result, "outer-mounted:/widgets")
+}
+
+// TestOnionMountDoesNotShadowUnmatchedRequests ensures a request that
+// doesn't match any registered Mount falls through to the receiver's own
+// handlers undisturbed, rather than being terminated by the ServeMux's
+// built-in 404 handler for the unmatched path.
+func TestOnionMountDoesNotShadowUnmatchedRequests(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	n := New()
+	n.Mount("/api", http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "mounted:" + r.URL.Path
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("ok"))
+	})
+
+	req, _ := http.NewRequest("GET", "/other/path", nil)
+	n.ServeHTTP(response, req)
+
+	expect(t, result, "")
+	expect(t, response.Body.String(), "ok")
+}
+
+// TestOnionMountMoreSpecificThanGroup ensures a Mount registered under a
+// Group's prefix still wins when its pattern is the more specific match,
+// rather than being shadowed by the group just because Group's dispatch is
+// checked first.
+func TestOnionMountMoreSpecificThanGroup(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	n := New()
+	n.Group("/admin")
+	n.Mount("/admin/users", http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "users:" + r.URL.Path
+	}))
+
+	req, _ := http.NewRequest("GET", "/admin/users/5", nil)
+	n.ServeHTTP(response, req)
+
+	expect(t, result, "users:/5")
+}
+
+func TestOnionGroup(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	n := New()
+	n.Use(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		result += "outer-"
+		next.ServeHTTP(rw, r)
+	}))
+
+	api := n.Group("/api", HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		result += "inner-"
+		next.ServeHTTP(rw, r)
+	}))
+	api.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "handler:" + r.URL.Path
+	})
+
+	req, _ := http.NewRequest("GET", "/api/widgets", nil)
+	n.ServeHTTP(response, req)
+
+	expect(t, result, "outer-inner-handler:/widgets")
+
+	// The parent Onion's own handlers are left untouched by Group.
+	expect(t, 1, len(n.Handlers()))
+}
+
+// TestOnionGroup_doNotModifyOriginal ensures a Group's handler chain is an
+// immutable snapshot, the same guarantee With makes: handlers added to the
+// parent after the group is created must not retroactively affect it.
+func TestOnionGroup_doNotModifyOriginal(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	n := New()
+	n.Use(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		result += "outer-"
+		next.ServeHTTP(rw, r)
+	}))
+
+	api := n.Group("/api")
+	api.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "handler:" + r.URL.Path
+	})
+
+	// Added to the parent after the group was created; must not run for
+	// requests dispatched to api.
+	n.Use(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		result += "late-"
+		next.ServeHTTP(rw, r)
+	}))
+
+	req, _ := http.NewRequest("GET", "/api/widgets", nil)
+	n.ServeHTTP(response, req)
+
+	expect(t, result, "outer-handler:/widgets")
+}
+
 type voidHandler struct{}
 
 func (vh *voidHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.Handler) {
@@ -211,3 +331,22 @@ func BenchmarkOnion(b *testing.B) {
 		n.ServeHTTP(nil, nil)
 	}
 }
+
+// BenchmarkOnionDeepChain exercises a much deeper stack than BenchmarkOnion
+// to demonstrate that dispatch allocates zero bytes per request regardless
+// of chain depth, since the iterator walking the handlers is pooled rather
+// than rebuilt per request.
+func BenchmarkOnionDeepChain(b *testing.B) {
+	handlers := make([]Handler, 100)
+	for i := range handlers {
+		handlers[i] = &voidHandler{}
+	}
+
+	n := New(handlers...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n.ServeHTTP(nil, nil)
+	}
+}
@@ -0,0 +1,97 @@
+package onion
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"runtime"
+)
+
+// Logger is the logging interface used by Recovery to report recovered
+// panics. The standard library's *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// RecoverError wraps a panic value caught by Recovery, together with the
+// stack trace captured at the point of recovery. Recovery attaches it to
+// the request's context, so middleware mounted earlier in the chain (and
+// therefore still on the call stack when next.ServeHTTP returns) can detect
+// and re-handle the panic instead of treating it as an opaque interface{}.
+type RecoverError struct {
+	// Recovered is the value passed to panic.
+	Recovered interface{}
+	// Stack is the stack trace captured at the point of recovery.
+	Stack []byte
+}
+
+func (e *RecoverError) Error() string {
+	return fmt.Sprintf("onion: recovered panic: %v", e.Recovered)
+}
+
+type recoverErrorKey struct{}
+
+// RecoverErrorFromRequest returns the RecoverError that Recovery attached to
+// r while handling a recovered panic, if any.
+func RecoverErrorFromRequest(r *http.Request) (*RecoverError, bool) {
+	err, ok := r.Context().Value(recoverErrorKey{}).(*RecoverError)
+	return err, ok
+}
+
+// PanicHandler is invoked by Recovery after a panic has been recovered, in
+// place of writing StatusCode, so callers can render a custom response.
+type PanicHandler func(rw http.ResponseWriter, r *http.Request, recovered interface{}, stack []byte)
+
+// Recovery is a Handler that recovers panics from downstream handlers,
+// writes a configurable status code, and logs the stack trace via Logger.
+// Construct one with NewRecovery and add it early in the chain with Use so
+// it wraps everything that runs after it.
+type Recovery struct {
+	// Logger receives the recovered panic and stack trace.
+	Logger Logger
+	// StatusCode is written to rw when a panic is recovered and no
+	// PanicHandler is set. Defaults to http.StatusInternalServerError.
+	StatusCode int
+	// PanicHandler, if set, is invoked instead of writing StatusCode,
+	// letting callers render a custom response for the recovered panic.
+	PanicHandler PanicHandler
+}
+
+// NewRecovery returns a Recovery middleware that logs to stderr via the
+// standard library's log package and writes a 500 status by default.
+func NewRecovery() *Recovery {
+	return &Recovery{
+		Logger:     log.New(os.Stderr, "[onion] ", log.LstdFlags),
+		StatusCode: http.StatusInternalServerError,
+	}
+}
+
+func (rec *Recovery) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		stack := make([]byte, 4096)
+		stack = stack[:runtime.Stack(stack, false)]
+
+		if rec.Logger != nil {
+			rec.Logger.Printf("PANIC: %v\n%s", recovered, stack)
+		}
+
+		recErr := &RecoverError{Recovered: recovered, Stack: stack}
+		*r = *r.WithContext(context.WithValue(r.Context(), recoverErrorKey{}, recErr))
+
+		if rec.PanicHandler != nil {
+			rec.PanicHandler(rw, r, recovered, stack)
+			return
+		}
+
+		rw.WriteHeader(rec.StatusCode)
+	}()
+
+	next.ServeHTTP(rw, r)
+}
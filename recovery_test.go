@@ -0,0 +1,92 @@
+package onion
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecoveryRecoversPanic(t *testing.T) {
+	response := httptest.NewRecorder()
+	var logBuf bytes.Buffer
+
+	rec := NewRecovery()
+	rec.Logger = log.New(&logBuf, "", 0)
+
+	n := New()
+	n.Use(rec)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	expect(t, response.Code, http.StatusInternalServerError)
+	if logBuf.Len() == 0 {
+		t.Errorf("expected Recovery to log the recovered panic, got nothing")
+	}
+}
+
+func TestRecoveryPanicHandler(t *testing.T) {
+	response := httptest.NewRecorder()
+
+	var handledWith interface{}
+	rec := NewRecovery()
+	rec.Logger = nil
+	rec.PanicHandler = func(rw http.ResponseWriter, r *http.Request, recovered interface{}, stack []byte) {
+		handledWith = recovered
+		rw.WriteHeader(http.StatusTeapot)
+	}
+
+	n := New()
+	n.Use(rec)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("teacup")
+	})
+
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	expect(t, response.Code, http.StatusTeapot)
+	expect(t, handledWith, "teacup")
+}
+
+func TestRecoveryAttachesRecoverErrorToRequest(t *testing.T) {
+	response := httptest.NewRecorder()
+
+	var sawErr *RecoverError
+	outer := HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		next.ServeHTTP(rw, r)
+		sawErr, _ = RecoverErrorFromRequest(r)
+	})
+
+	rec := NewRecovery()
+	rec.Logger = nil
+
+	n := New(outer, rec)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("leaked")
+	})
+
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	if sawErr == nil {
+		t.Fatalf("expected outer middleware to observe a RecoverError, got nil")
+	}
+	expect(t, sawErr.Recovered, "leaked")
+}
+
+func TestRecoveryNoPanic(t *testing.T) {
+	response := httptest.NewRecorder()
+
+	rec := NewRecovery()
+	n := New()
+	n.Use(rec)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+	expect(t, response.Code, http.StatusOK)
+}
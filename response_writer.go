@@ -0,0 +1,299 @@
+package onion
+
+import (
+	"net/http"
+	"sync"
+)
+
+// ResponseWriter is a wrapper around http.ResponseWriter that tracks the
+// status code and body size written to the response, so that logging,
+// metrics, and compression middleware can inspect them after a downstream
+// handler has written the response, without each reimplementing the same
+// wrapping.
+type ResponseWriter interface {
+	http.ResponseWriter
+
+	// Status returns the status code of the response, or 0 if the response
+	// has not been written yet.
+	Status() int
+	// Size returns the number of bytes already written into the response
+	// body.
+	Size() int
+	// Written reports whether the response has been written to yet.
+	Written() bool
+	// Before registers a function to be called just before the response is
+	// written for the first time. Functions run in the reverse order they
+	// were registered, and may still set response headers.
+	Before(func(ResponseWriter))
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int
+	beforeFuncs []func(ResponseWriter)
+	// self is the outermost ResponseWriter wrapOptional returned for this
+	// responseWriter - itself when underlying implements none of the
+	// optional interfaces, one of the capability wrapper types otherwise.
+	// Before hooks are called with self, not rw, so a hook's own feature
+	// detection (e.g. rw.(http.Flusher)) sees the same capabilities the
+	// caller holding the top-level ResponseWriter does.
+	self ResponseWriter
+}
+
+// NewResponseWriter wraps rw, returning a ResponseWriter that tracks the
+// status code and size of the response written through it. The result only
+// implements http.Hijacker, http.Flusher, http.CloseNotifier, and
+// http.Pusher when rw itself does, so callers doing feature detection
+// (e.g. rw.(http.Flusher)) see an accurate answer instead of a silent
+// no-op or a panic.
+func NewResponseWriter(rw http.ResponseWriter) ResponseWriter {
+	return wrapOptional(&responseWriter{ResponseWriter: rw}, rw)
+}
+
+// wrapOptional picks the wrapper type whose embedded interfaces exactly
+// match the optional interfaces (http.Hijacker, http.Flusher,
+// http.CloseNotifier, http.Pusher) that underlying implements. A method
+// promoted from an embedded field is part of the concrete type's method
+// set only when that field's static type declares it, so reaching an
+// accurate result for every combination takes one named type per
+// combination rather than a single struct forwarding unconditionally.
+//
+// The combination of all four is pooled like the base *responseWriter;
+// every other combination allocates its wrapper normally. That includes
+// net/http's HTTP/1 ResponseWriter, which implements http.Hijacker,
+// http.Flusher and http.CloseNotifier but not http.Pusher.
+func wrapOptional(w *responseWriter, underlying http.ResponseWriter) ResponseWriter {
+	h, hasHijacker := underlying.(http.Hijacker)
+	f, hasFlusher := underlying.(http.Flusher)
+	c, hasCloseNotifier := underlying.(http.CloseNotifier)
+	p, hasPusher := underlying.(http.Pusher)
+
+	var wrapped ResponseWriter
+	switch {
+	case hasHijacker && hasFlusher && hasCloseNotifier && hasPusher:
+		full := hijackFlusherCloseNotifierPusherWriterPool.Get().(*hijackFlusherCloseNotifierPusherWriter)
+		full.responseWriter, full.Hijacker, full.Flusher, full.CloseNotifier, full.Pusher = w, h, f, c, p
+		wrapped = full
+	case hasHijacker && hasFlusher && hasCloseNotifier:
+		wrapped = &hijackFlusherCloseNotifierWriter{w, h, f, c}
+	case hasHijacker && hasFlusher && hasPusher:
+		wrapped = &hijackFlusherPusherWriter{w, h, f, p}
+	case hasHijacker && hasCloseNotifier && hasPusher:
+		wrapped = &hijackCloseNotifierPusherWriter{w, h, c, p}
+	case hasFlusher && hasCloseNotifier && hasPusher:
+		wrapped = &flusherCloseNotifierPusherWriter{w, f, c, p}
+	case hasHijacker && hasFlusher:
+		wrapped = &hijackFlusherWriter{w, h, f}
+	case hasHijacker && hasCloseNotifier:
+		wrapped = &hijackCloseNotifierWriter{w, h, c}
+	case hasHijacker && hasPusher:
+		wrapped = &hijackPusherWriter{w, h, p}
+	case hasFlusher && hasCloseNotifier:
+		wrapped = &flusherCloseNotifierWriter{w, f, c}
+	case hasFlusher && hasPusher:
+		wrapped = &flusherPusherWriter{w, f, p}
+	case hasCloseNotifier && hasPusher:
+		wrapped = &closeNotifierPusherWriter{w, c, p}
+	case hasHijacker:
+		wrapped = &hijackWriter{w, h}
+	case hasFlusher:
+		wrapped = &flusherWriter{w, f}
+	case hasCloseNotifier:
+		wrapped = &closeNotifierWriter{w, c}
+	case hasPusher:
+		wrapped = &pusherWriter{w, p}
+	default:
+		wrapped = w
+	}
+
+	w.self = wrapped
+	return wrapped
+}
+
+type hijackWriter struct {
+	*responseWriter
+	http.Hijacker
+}
+
+type flusherWriter struct {
+	*responseWriter
+	http.Flusher
+}
+
+type closeNotifierWriter struct {
+	*responseWriter
+	http.CloseNotifier
+}
+
+type pusherWriter struct {
+	*responseWriter
+	http.Pusher
+}
+
+type hijackFlusherWriter struct {
+	*responseWriter
+	http.Hijacker
+	http.Flusher
+}
+
+type hijackCloseNotifierWriter struct {
+	*responseWriter
+	http.Hijacker
+	http.CloseNotifier
+}
+
+type hijackPusherWriter struct {
+	*responseWriter
+	http.Hijacker
+	http.Pusher
+}
+
+type flusherCloseNotifierWriter struct {
+	*responseWriter
+	http.Flusher
+	http.CloseNotifier
+}
+
+type flusherPusherWriter struct {
+	*responseWriter
+	http.Flusher
+	http.Pusher
+}
+
+type closeNotifierPusherWriter struct {
+	*responseWriter
+	http.CloseNotifier
+	http.Pusher
+}
+
+type hijackFlusherCloseNotifierWriter struct {
+	*responseWriter
+	http.Hijacker
+	http.Flusher
+	http.CloseNotifier
+}
+
+type hijackFlusherPusherWriter struct {
+	*responseWriter
+	http.Hijacker
+	http.Flusher
+	http.Pusher
+}
+
+type hijackCloseNotifierPusherWriter struct {
+	*responseWriter
+	http.Hijacker
+	http.CloseNotifier
+	http.Pusher
+}
+
+type flusherCloseNotifierPusherWriter struct {
+	*responseWriter
+	http.Flusher
+	http.CloseNotifier
+	http.Pusher
+}
+
+type hijackFlusherCloseNotifierPusherWriter struct {
+	*responseWriter
+	http.Hijacker
+	http.Flusher
+	http.CloseNotifier
+	http.Pusher
+}
+
+var responseWriterPool = sync.Pool{
+	New: func() interface{} { return new(responseWriter) },
+}
+
+// getResponseWriter is like NewResponseWriter, but draws the wrapper from a
+// pool so that Onion's dispatch doesn't allocate one per request. Pair every
+// call with putResponseWriter once the response has been served.
+func getResponseWriter(rw http.ResponseWriter) *responseWriter {
+	w := responseWriterPool.Get().(*responseWriter)
+	w.ResponseWriter = rw
+	w.status = 0
+	w.size = 0
+	w.beforeFuncs = w.beforeFuncs[:0]
+	w.self = w
+	return w
+}
+
+func putResponseWriter(w *responseWriter) {
+	w.ResponseWriter = nil
+	w.self = nil
+	responseWriterPool.Put(w)
+}
+
+var hijackFlusherCloseNotifierPusherWriterPool = sync.Pool{
+	New: func() interface{} { return new(hijackFlusherCloseNotifierPusherWriter) },
+}
+
+// unwrap returns the *responseWriter that every wrapOptional result
+// embeds, regardless of which optional interfaces it also wraps.
+func (rw *responseWriter) unwrap() *responseWriter {
+	return rw
+}
+
+// putWrapped releases a ResponseWriter obtained through wrapOptional back
+// to the pool(s) it was drawn from. Pair every wrapOptional call with one
+// putWrapped call once the response has been served.
+func putWrapped(rw ResponseWriter) {
+	if full, ok := rw.(*hijackFlusherCloseNotifierPusherWriter); ok {
+		putResponseWriter(full.responseWriter)
+		*full = hijackFlusherCloseNotifierPusherWriter{}
+		hijackFlusherCloseNotifierPusherWriterPool.Put(full)
+		return
+	}
+	if u, ok := rw.(interface{ unwrap() *responseWriter }); ok {
+		putResponseWriter(u.unwrap())
+	}
+}
+
+func (rw *responseWriter) WriteHeader(s int) {
+	if rw.Written() {
+		// Matches the net/http convention that a second WriteHeader call is
+		// superfluous: guarding here, rather than forwarding it, keeps
+		// Status/Size accurate and Before hooks from firing more than once.
+		return
+	}
+
+	rw.callBefore()
+	rw.ResponseWriter.WriteHeader(s)
+	rw.status = s
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if !rw.Written() {
+		// The status will be StatusOK if WriteHeader has not been called yet
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	size, err := rw.ResponseWriter.Write(b)
+	rw.size += size
+	return size, err
+}
+
+func (rw *responseWriter) Status() int {
+	return rw.status
+}
+
+func (rw *responseWriter) Size() int {
+	return rw.size
+}
+
+func (rw *responseWriter) Written() bool {
+	return rw.status != 0
+}
+
+func (rw *responseWriter) Before(before func(ResponseWriter)) {
+	rw.beforeFuncs = append(rw.beforeFuncs, before)
+}
+
+func (rw *responseWriter) callBefore() {
+	for i := len(rw.beforeFuncs) - 1; i >= 0; i-- {
+		rw.beforeFuncs[i](rw.self)
+	}
+}
+
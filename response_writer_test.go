@@ -0,0 +1,202 @@
+package onion
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseWriterTracksStatusAndSize(t *testing.T) {
+	response := httptest.NewRecorder()
+	rw := NewResponseWriter(response)
+
+	expect(t, rw.Written(), false)
+	expect(t, rw.Status(), 0)
+
+	n, err := rw.Write([]byte("hello"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect(t, n, 5)
+
+	expect(t, rw.Written(), true)
+	expect(t, rw.Status(), http.StatusOK)
+	expect(t, rw.Size(), 5)
+}
+
+func TestResponseWriterWriteHeaderOnce(t *testing.T) {
+	response := httptest.NewRecorder()
+	rw := NewResponseWriter(response)
+
+	rw.WriteHeader(http.StatusNotFound)
+	expect(t, rw.Status(), http.StatusNotFound)
+	expect(t, response.Code, http.StatusNotFound)
+}
+
+func TestResponseWriterIgnoresSecondWriteHeader(t *testing.T) {
+	response := httptest.NewRecorder()
+	rw := NewResponseWriter(response)
+
+	beforeCalls := 0
+	rw.Before(func(rw ResponseWriter) {
+		beforeCalls++
+	})
+
+	rw.WriteHeader(http.StatusOK)
+	rw.WriteHeader(http.StatusNotFound)
+
+	expect(t, rw.Status(), http.StatusOK)
+	expect(t, response.Code, http.StatusOK)
+	expect(t, beforeCalls, 1)
+}
+
+func TestResponseWriterBefore(t *testing.T) {
+	response := httptest.NewRecorder()
+	rw := NewResponseWriter(response)
+
+	calledWithStatus := 0
+	rw.Before(func(rw ResponseWriter) {
+		calledWithStatus = rw.Status()
+	})
+
+	rw.WriteHeader(http.StatusAccepted)
+
+	// Before fires just before the status is recorded, so it observes the
+	// zero value, not the status about to be written.
+	expect(t, calledWithStatus, 0)
+	expect(t, rw.Status(), http.StatusAccepted)
+}
+
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (h *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	rw := NewResponseWriter(&hijackableRecorder{httptest.NewRecorder()})
+
+	hijacker, ok := rw.(http.Hijacker)
+	if !ok {
+		t.Fatalf("expected wrapped ResponseWriter to implement http.Hijacker")
+	}
+	if _, _, err := hijacker.Hijack(); err != nil {
+		t.Fatalf("unexpected error from Hijack: %v", err)
+	}
+}
+
+func TestResponseWriterHijackUnsupported(t *testing.T) {
+	rw := NewResponseWriter(httptest.NewRecorder())
+
+	if _, ok := rw.(http.Hijacker); ok {
+		t.Errorf("expected wrapped ResponseWriter not to implement http.Hijacker when the underlying writer doesn't")
+	}
+}
+
+type flushableRecorder struct {
+	*httptest.ResponseRecorder
+	flushed bool
+}
+
+func (f *flushableRecorder) Flush() {
+	f.flushed = true
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	underlying := &flushableRecorder{ResponseRecorder: httptest.NewRecorder()}
+	rw := NewResponseWriter(underlying)
+
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		t.Fatalf("expected wrapped ResponseWriter to implement http.Flusher")
+	}
+	flusher.Flush()
+	expect(t, underlying.flushed, true)
+}
+
+// TestResponseWriterBeforeSeesCapabilities ensures a Before hook is called
+// with the same capability-wrapped ResponseWriter NewResponseWriter
+// returned, not the bare inner writer, so the hook's own feature detection
+// (e.g. w.(http.Flusher)) reflects what the underlying writer actually
+// supports.
+func TestResponseWriterBeforeSeesCapabilities(t *testing.T) {
+	underlying := &flushableRecorder{ResponseRecorder: httptest.NewRecorder()}
+	rw := NewResponseWriter(underlying)
+
+	sawFlusher := false
+	rw.Before(func(w ResponseWriter) {
+		_, sawFlusher = w.(http.Flusher)
+	})
+
+	rw.WriteHeader(http.StatusOK)
+
+	expect(t, sawFlusher, true)
+}
+
+// bareResponseWriter implements only http.ResponseWriter, none of the
+// optional Hijacker/Flusher/CloseNotifier/Pusher interfaces -
+// httptest.ResponseRecorder, unlike a real connection, already implements
+// Flush itself, so it can't stand in for "doesn't support Flush".
+type bareResponseWriter struct {
+	header http.Header
+}
+
+func (w *bareResponseWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = http.Header{}
+	}
+	return w.header
+}
+func (w *bareResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *bareResponseWriter) WriteHeader(int)              {}
+
+func TestResponseWriterFlushUnsupported(t *testing.T) {
+	rw := NewResponseWriter(&bareResponseWriter{})
+
+	if _, ok := rw.(http.Flusher); ok {
+		t.Errorf("expected wrapped ResponseWriter not to implement http.Flusher when the underlying writer doesn't")
+	}
+}
+
+func TestResponseWriterCloseNotifyUnsupported(t *testing.T) {
+	rw := NewResponseWriter(httptest.NewRecorder())
+
+	if _, ok := rw.(http.CloseNotifier); ok {
+		t.Errorf("expected wrapped ResponseWriter not to implement http.CloseNotifier when the underlying writer doesn't")
+	}
+}
+
+func TestResponseWriterPushUnsupported(t *testing.T) {
+	rw := NewResponseWriter(httptest.NewRecorder())
+
+	if _, ok := rw.(http.Pusher); ok {
+		t.Errorf("expected wrapped ResponseWriter not to implement http.Pusher when the underlying writer doesn't")
+	}
+}
+
+func TestOnionWrapsResponseWriter(t *testing.T) {
+	response := httptest.NewRecorder()
+
+	var seen ResponseWriter
+	n := New()
+	n.Use(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		var ok bool
+		seen, ok = rw.(ResponseWriter)
+		if !ok {
+			t.Fatalf("expected downstream handler to see an onion.ResponseWriter")
+		}
+		next.ServeHTTP(rw, r)
+	}))
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("ok"))
+	})
+
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	expect(t, seen.Status(), http.StatusOK)
+	expect(t, seen.Size(), 2)
+}
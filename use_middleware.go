@@ -0,0 +1,31 @@
+package onion
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// UseMiddleware adds one or more middlewares onto the stack, accepting any
+// of the shapes commonly used across the Go http middleware ecosystem:
+// Handler, HandlerFunc, http.Handler, http.HandlerFunc, or
+// func(http.Handler) http.Handler. This lets users drop in third-party
+// middleware (e.g. from the chi/gorilla-mux ecosystem) without manually
+// calling Wrap or FromConstructor themselves.
+func (n *Onion) UseMiddleware(middlewares ...interface{}) {
+	for _, m := range middlewares {
+		n.Use(adaptMiddleware(m))
+	}
+}
+
+func adaptMiddleware(m interface{}) Handler {
+	switch h := m.(type) {
+	case Handler:
+		return h
+	case http.Handler:
+		return Wrap(h)
+	case func(http.Handler) http.Handler:
+		return FromConstructor(h)
+	default:
+		panic(fmt.Sprintf("onion: UseMiddleware: unsupported middleware type %T", m))
+	}
+}
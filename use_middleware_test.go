@@ -0,0 +1,85 @@
+package onion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUseMiddlewareAcceptsOnionHandler(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	n := New()
+	n.UseMiddleware(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+		result += "onion-"
+		next.ServeHTTP(rw, r)
+	}))
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	expect(t, result, "onion-")
+}
+
+func TestUseMiddlewareAcceptsHTTPHandler(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	n := New()
+	n.UseMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "http-"
+	}))
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	expect(t, result, "http-")
+}
+
+func TestUseMiddlewareAcceptsConstructor(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	ctor := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			result += "ctor-"
+			next.ServeHTTP(rw, r)
+		})
+	}
+
+	n := New()
+	n.UseMiddleware(ctor)
+	n.UseHandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result += "handler"
+	})
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	expect(t, result, "ctor-handler")
+}
+
+func TestUseMiddlewareMultiple(t *testing.T) {
+	result := ""
+	response := httptest.NewRecorder()
+
+	n := New()
+	n.UseMiddleware(
+		HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.Handler) {
+			result += "one-"
+			next.ServeHTTP(rw, r)
+		}),
+		http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			result += "two-"
+		}),
+	)
+	n.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+
+	expect(t, result, "one-two-")
+}
+
+func TestUseMiddlewareUnsupportedTypePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected UseMiddleware to panic on an unsupported type, but it did not")
+		}
+	}()
+
+	n := New()
+	n.UseMiddleware(42)
+}